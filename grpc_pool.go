@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	pb "github.com/vagababov/prime-server/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var grpcPoolSize = flag.Int("grpc_pool_size", 4,
+	"Number of persistent gRPC connections to keep open to the backend")
+
+// grpcPool maintains a fixed set of long-lived connections to the backend
+// and hands them out round-robin, so callers don't pay a dial+handshake
+// on every request. Connections are opened lazily on first use; one the
+// backend reports unavailable is evicted in the background and re-dialed
+// on the next use of its slot.
+type grpcPool struct {
+	target string
+	opts   []grpc.DialOption
+
+	mu    sync.Mutex
+	conns []*grpc.ClientConn
+
+	next uint64
+}
+
+// newGRPCPool creates a pool of size connections against target. No
+// dialing happens until the first Get(). size must be positive, since
+// Get() indexes into it modulo the pool size.
+func newGRPCPool(target string, size int, opts ...grpc.DialOption) (*grpcPool, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("grpc pool size must be positive, got %d", size)
+	}
+	return &grpcPool{
+		target: target,
+		opts:   opts,
+		conns:  make([]*grpc.ClientConn, size),
+	}, nil
+}
+
+// connAt returns the connection in slot i, dialing it if this is the
+// first use of that slot.
+func (p *grpcPool) connAt(i int) (*grpc.ClientConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conns[i] != nil {
+		return p.conns[i], nil
+	}
+	// p.target is passed through unmodified, so unix:// and
+	// unix-abstract:// targets resolve via grpc-go's built-in unix
+	// resolver without any special-casing here.
+	conn, err := grpc.DialContext(context.Background(), p.target, p.opts...)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[i] = conn
+	return conn, nil
+}
+
+// Close closes every connection currently dialed in the pool. Callers
+// that swap in a replacement pool (e.g. redialGRPCPool on SIGHUP) should
+// Close the old one afterwards so its sockets and keepalive goroutines
+// don't leak.
+func (p *grpcPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, conn := range p.conns {
+		if conn != nil {
+			conn.Close()
+			p.conns[i] = nil
+		}
+	}
+}
+
+// evict closes and clears the connection in slot i, so the next caller
+// to land on that slot re-dials a fresh one.
+func (p *grpcPool) evict(i int) {
+	p.mu.Lock()
+	conn := p.conns[i]
+	p.conns[i] = nil
+	p.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// poolClient is a gRPC client bound to one slot of the pool, so a failed
+// call can report its slot back for eviction. conn is kept around too,
+// for callers (e.g. the readiness probe) that need a service client other
+// than PrimeServiceClient on the same connection.
+type poolClient struct {
+	pb.PrimeServiceClient
+	conn *grpc.ClientConn
+	pool *grpcPool
+	idx  int
+}
+
+// Call issues the Get RPC through this client's connection. If the
+// backend reports the connection as unavailable, the slot is evicted in
+// the background so it gets re-dialed on its next use.
+func (c *poolClient) Call(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	resp, err := c.PrimeServiceClient.Get(ctx, req)
+	if status.Code(err) == codes.Unavailable {
+		go c.pool.evict(c.idx)
+	}
+	return resp, err
+}
+
+// Get returns the next client in round-robin order (atomic counter mod
+// pool size), dialing its connection if this is its first use.
+func (p *grpcPool) Get(ctx context.Context) (*poolClient, error) {
+	idx := int(atomic.AddUint64(&p.next, 1) % uint64(len(p.conns)))
+	conn, err := p.connAt(idx)
+	if err != nil {
+		return nil, fmt.Errorf("dialing backend: %w", err)
+	}
+	return &poolClient{
+		PrimeServiceClient: pb.NewPrimeServiceClient(conn),
+		conn:               conn,
+		pool:               p,
+		idx:                idx,
+	}, nil
+}
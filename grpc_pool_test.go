@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+// grpc.DialContext without grpc.WithBlock() returns before actually
+// connecting, so these tests can exercise pool bookkeeping against a
+// target that isn't listening.
+const fakeTarget = "127.0.0.1:0"
+
+func TestGrpcPoolGetRoundRobins(t *testing.T) {
+	pool, err := newGRPCPool(fakeTarget, 3, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("newGRPCPool: %v", err)
+	}
+
+	seen := make(map[int]bool)
+	for i := 0; i < 6; i++ {
+		client, err := pool.Get(context.Background())
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		seen[client.idx] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Get visited %d distinct slots, want 3", len(seen))
+	}
+}
+
+func TestNewGRPCPoolRejectsNonPositiveSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		if _, err := newGRPCPool(fakeTarget, size, grpc.WithInsecure()); err == nil {
+			t.Errorf("newGRPCPool(size=%d) = nil error, want error", size)
+		}
+	}
+}
+
+func TestGrpcPoolEvictForcesRedial(t *testing.T) {
+	pool, err := newGRPCPool(fakeTarget, 1, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("newGRPCPool: %v", err)
+	}
+
+	first, err := pool.connAt(0)
+	if err != nil {
+		t.Fatalf("connAt: %v", err)
+	}
+
+	pool.evict(0)
+
+	second, err := pool.connAt(0)
+	if err != nil {
+		t.Fatalf("connAt after evict: %v", err)
+	}
+	if first == second {
+		t.Error("connAt returned the same connection after evict, want a fresh dial")
+	}
+}
+
+func TestGrpcPoolClose(t *testing.T) {
+	pool, err := newGRPCPool(fakeTarget, 2, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("newGRPCPool: %v", err)
+	}
+	if _, err := pool.connAt(0); err != nil {
+		t.Fatalf("connAt: %v", err)
+	}
+	if _, err := pool.connAt(1); err != nil {
+		t.Fatalf("connAt: %v", err)
+	}
+
+	pool.Close()
+
+	for i, conn := range pool.conns {
+		if conn != nil {
+			t.Errorf("slot %d still holds a connection after Close", i)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var readinessTTL = flag.Duration("readiness_ttl", time.Second,
+	"How long to cache the backend readiness probe result, to avoid hammering the backend under frequent kubelet probing")
+
+// readinessProbeTimeout bounds a single backend probe, so a wedged
+// backend can't make /readyz itself hang.
+const readinessProbeTimeout = 250 * time.Millisecond
+
+// readiness caches the outcome of the last backend probe for
+// -readiness_ttl.
+var readiness = &readinessCache{probe: probeBackend}
+
+// readinessCache caches the outcome of probe for -readiness_ttl. probe is
+// a field rather than a direct call to probeBackend so tests can stub it
+// out without touching the real backend.
+type readinessCache struct {
+	probe func() bool
+
+	mu        sync.Mutex
+	ready     bool
+	checkedAt time.Time
+}
+
+// isReady returns the cached probe result, refreshing it first if it's
+// older than -readiness_ttl.
+func (c *readinessCache) isReady() bool {
+	c.mu.Lock()
+	if time.Since(c.checkedAt) < *readinessTTL {
+		ready := c.ready
+		c.mu.Unlock()
+		return ready
+	}
+	c.mu.Unlock()
+
+	ready := c.probe()
+
+	c.mu.Lock()
+	c.ready = ready
+	c.checkedAt = time.Now()
+	c.mu.Unlock()
+	return ready
+}
+
+// probeBackend makes a single direct check of the configured backend: a
+// gRPC health check when -use_grpc, a plain HEAD otherwise.
+func probeBackend() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), readinessProbeTimeout)
+	defer cancel()
+
+	if *useGRPC {
+		return probeGRPCBackend(ctx)
+	}
+	return probeHTTPBackend(ctx)
+}
+
+func probeGRPCBackend(ctx context.Context) bool {
+	client, err := pool.Load().Get(ctx)
+	if err != nil {
+		return false
+	}
+	resp, err := grpc_health_v1.NewHealthClient(client.conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return false
+	}
+	return resp.Status == grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+func probeHTTPBackend(ctx context.Context) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, backendURL(), nil)
+	if err != nil {
+		return false
+	}
+	if *host != "" {
+		req.Host = *host
+	}
+	resp, err := httpClientFor(*backend).Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode < http.StatusInternalServerError
+}
+
+// handlerLivez is the liveness probe: it reports healthy as long as the
+// process can handle the request at all.
+func handlerLivez(ctx *gin.Context) {
+	ctx.Status(http.StatusOK)
+}
+
+// handlerReadyz is the readiness probe: it reports healthy only while the
+// configured backend is reachable.
+func handlerReadyz(ctx *gin.Context) {
+	if !readiness.isReady() {
+		ctx.Status(http.StatusServiceUnavailable)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
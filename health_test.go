@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadinessCacheCachesWithinTTL(t *testing.T) {
+	origTTL := *readinessTTL
+	*readinessTTL = time.Minute
+	defer func() { *readinessTTL = origTTL }()
+
+	calls := 0
+	c := &readinessCache{probe: func() bool {
+		calls++
+		return true
+	}}
+
+	for i := 0; i < 3; i++ {
+		if !c.isReady() {
+			t.Fatalf("isReady() = false, want true")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("probe called %d times within TTL, want 1", calls)
+	}
+}
+
+func TestReadinessCacheRefreshesAfterTTL(t *testing.T) {
+	origTTL := *readinessTTL
+	*readinessTTL = time.Millisecond
+	defer func() { *readinessTTL = origTTL }()
+
+	calls := 0
+	c := &readinessCache{probe: func() bool {
+		calls++
+		return calls == 1
+	}}
+
+	if !c.isReady() {
+		t.Fatalf("first isReady() = false, want true")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if c.isReady() {
+		t.Errorf("second isReady() = true after TTL expired and probe flipped, want false")
+	}
+	if calls != 2 {
+		t.Errorf("probe called %d times across the TTL boundary, want 2", calls)
+	}
+}
+
+func TestHandlerLivezAlwaysOK(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/healthz", nil)
+
+	handlerLivez(ctx)
+	ctx.Writer.WriteHeaderNow()
+
+	if w.Code != 200 {
+		t.Errorf("handlerLivez status = %d, want 200", w.Code)
+	}
+}
+
+func TestHandlerReadyzReflectsCache(t *testing.T) {
+	origTTL := *readinessTTL
+	*readinessTTL = time.Minute
+	defer func() { *readinessTTL = origTTL }()
+
+	origReadiness := readiness
+	defer func() { readiness = origReadiness }()
+
+	gin.SetMode(gin.TestMode)
+
+	readiness = &readinessCache{probe: func() bool { return false }}
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/readyz", nil)
+	handlerReadyz(ctx)
+	ctx.Writer.WriteHeaderNow()
+	if w.Code != 503 {
+		t.Errorf("handlerReadyz status = %d with an unready backend, want 503", w.Code)
+	}
+
+	readiness = &readinessCache{probe: func() bool { return true }}
+	w = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/readyz", nil)
+	handlerReadyz(ctx)
+	ctx.Writer.WriteHeaderNow()
+	if w.Code != 200 {
+		t.Errorf("handlerReadyz status = %d with a ready backend, want 200", w.Code)
+	}
+}
@@ -0,0 +1,57 @@
+// Package tlsconfig builds *tls.Config values for the client's gRPC (and,
+// eventually, server) transports from CA/cert/key file flags, so the same
+// loading logic isn't duplicated wherever TLS is wired up.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// Options describes the files used to build a *tls.Config. CA is optional;
+// when empty, the system trust roots are used. Cert and Key must be set
+// together, and are needed only for mutual TLS.
+// ServerName overrides the SNI/authority sent to the backend.
+type Options struct {
+	CA         string
+	Cert       string
+	Key        string
+	ServerName string
+}
+
+// Load builds a *tls.Config from opts. If opts.CA is set, it's used as the
+// trust root instead of the system pool. If opts.Cert and opts.Key are
+// both set, the resulting config also presents a client certificate,
+// enabling mutual TLS.
+func Load(opts Options) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName: opts.ServerName,
+	}
+
+	if opts.CA != "" {
+		pem, err := os.ReadFile(opts.CA)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file %q: %w", opts.CA, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", opts.CA)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if opts.Cert != "" || opts.Key != "" {
+		if opts.Cert == "" || opts.Key == "" {
+			return nil, fmt.Errorf("both cert and key must be set for mutual TLS, got cert=%q key=%q", opts.Cert, opts.Key)
+		}
+		cert, err := tls.LoadX509KeyPair(opts.Cert, opts.Key)
+		if err != nil {
+			return nil, fmt.Errorf("loading client keypair: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,112 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCert generates a throwaway self-signed cert/key pair and
+// writes them as PEM files under dir, returning their paths.
+func writeSelfSignedCert(t *testing.T, dir string) (certPath, keyPath string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshaling key: %v", err)
+	}
+
+	certPath = filepath.Join(dir, "cert.pem")
+	keyPath = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("writing cert: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("writing key: %v", err)
+	}
+	return certPath, keyPath
+}
+
+func TestLoadNoFilesReturnsBareConfig(t *testing.T) {
+	cfg, err := Load(Options{ServerName: "backend.example.com"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.ServerName != "backend.example.com" {
+		t.Errorf("ServerName = %q, want %q", cfg.ServerName, "backend.example.com")
+	}
+	if cfg.RootCAs != nil {
+		t.Error("RootCAs set with no CA configured")
+	}
+	if len(cfg.Certificates) != 0 {
+		t.Error("Certificates set with no cert/key configured")
+	}
+}
+
+func TestLoadCAMissingFile(t *testing.T) {
+	if _, err := Load(Options{CA: filepath.Join(t.TempDir(), "missing-ca.pem")}); err == nil {
+		t.Error("Load with a missing CA file returned nil error, want error")
+	}
+}
+
+func TestLoadCAInvalidPEM(t *testing.T) {
+	dir := t.TempDir()
+	badCA := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(badCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("writing bad CA file: %v", err)
+	}
+	if _, err := Load(Options{CA: badCA}); err == nil {
+		t.Error("Load with an invalid CA PEM returned nil error, want error")
+	}
+}
+
+func TestLoadCertWithoutKey(t *testing.T) {
+	dir := t.TempDir()
+	certPath, _ := writeSelfSignedCert(t, dir)
+	if _, err := Load(Options{Cert: certPath}); err == nil {
+		t.Error("Load with Cert but no Key returned nil error, want error")
+	}
+}
+
+func TestLoadKeyWithoutCert(t *testing.T) {
+	dir := t.TempDir()
+	_, keyPath := writeSelfSignedCert(t, dir)
+	if _, err := Load(Options{Key: keyPath}); err == nil {
+		t.Error("Load with Key but no Cert returned nil error, want error")
+	}
+}
+
+func TestLoadMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeSelfSignedCert(t, dir)
+
+	cfg, err := Load(Options{Cert: certPath, Key: keyPath, ServerName: "backend"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Certificates) != 1 {
+		t.Fatalf("Certificates = %d entries, want 1", len(cfg.Certificates))
+	}
+}
@@ -11,13 +11,18 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"path"
+	"os/signal"
 	"strconv"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/vagababov/prime-client/internal/tlsconfig"
 	pb "github.com/vagababov/prime-server/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -31,8 +36,18 @@ var (
 	host     = flag.String("host", "", "The host name to use if client runs outside of the cluster")
 	insecure = flag.Bool("insecure", true, "true if we want to skip SSL certificate for gRPC calls")
 	useGRPC  = flag.Bool("use_grpc", false, "If true, the service will use gRPC to talk to the backend")
+
+	tlsCA         = flag.String("tls_ca", "", "Path to a PEM CA bundle to trust for the gRPC backend when -insecure=false; the system roots are used when unset")
+	tlsCert       = flag.String("tls_cert", "", "Path to a PEM client certificate; combined with -tls_key enables mutual TLS")
+	tlsKey        = flag.String("tls_key", "", "Path to the PEM private key matching -tls_cert")
+	tlsServerName = flag.String("tls_server_name", "", "SNI/authority to send to the backend over TLS; defaults to -host")
 )
 
+// pool holds the long-lived set of gRPC connections to *backend. It's
+// rebuilt and swapped in atomically by redialGRPCPool, so queryGRPC and
+// probeGRPCBackend can safely read it concurrently with a reload.
+var pool atomic.Pointer[grpcPool]
+
 const (
 	koPathEnvVar = "KO_DATA_PATH"
 	koPathDefVal = "./kodata/"
@@ -41,32 +56,68 @@ const (
 func main() {
 	flag.Parse()
 
-	// router
-	r := gin.New()
-	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
+	if err := redialGRPCPool(); err != nil {
+		log.Fatalf("failed to set up gRPC pool: %v", err)
+	}
+
+	go startAdminServer()
 
 	koPath := getEnv(koPathEnvVar, koPathDefVal)
 	fmt.Printf("KO Path: %q\n", koPath)
 
-	// static
-	r.LoadHTMLFiles(path.Join(koPath, "index.html"))
-	r.Static("/img", path.Join(koPath, "static/img"))
-	r.Static("/css", path.Join(koPath, "static/css"))
-
-	// routes
-	r.GET("/", handlerDef)
-	r.GET("/prime", handler)
-
-	// port
 	port := getEnv(portVariableName, defaultPort)
 	addr := ":" + port
+	srv := NewServer(koPath)
+	ln, err := srv.Listen(addr)
+	if err != nil {
+		log.Fatal(err)
+	}
 	fmt.Printf("Server starting: %s \n", addr)
-	if err := r.Run(addr); err != nil {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		for sig := range sigCh {
+			if sig == syscall.SIGHUP {
+				fmt.Println("Received SIGHUP, forcing a fresh gRPC dial")
+				if err := redialGRPCPool(); err != nil {
+					log.Printf("failed to redial gRPC pool: %v", err)
+				}
+				continue
+			}
+			cancel()
+			return
+		}
+	}()
+
+	if err := srv.Serve(ctx, ln); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// redialGRPCPool builds a fresh gRPC connection pool against *backend and
+// atomically swaps it in, closing the previous pool's connections so they
+// don't leak. It's invoked once at startup and again on every SIGHUP to
+// force new dials (e.g. to pick up a rotated TLS cert from disk); -backend,
+// -use_grpc, and the other flags are only ever read from argv once at
+// startup, so a SIGHUP can't change what this process is configured to
+// talk to.
+func redialGRPCPool() error {
+	dialOpts, err := grpcDialOpts()
+	if err != nil {
+		return err
+	}
+	newPool, err := newGRPCPool(*backend, *grpcPoolSize, dialOpts...)
+	if err != nil {
+		return err
+	}
+	if oldPool := pool.Swap(newPool); oldPool != nil {
+		oldPool.Close()
+	}
+	return nil
+}
+
 func handler(ctx *gin.Context) {
 	param := ctx.DefaultQuery("query", "4")
 	qint, err := strconv.ParseInt(param, 10 /*base*/, 64 /*bitcnt*/)
@@ -87,11 +138,15 @@ func handler(ctx *gin.Context) {
 
 func doHTTP(ctx *gin.Context, query *pb.Request) {
 	fmt.Println("HTTP pill is taken")
+	start := time.Now()
+	var err error
+	defer func() { observeRequest("http", start, err) }()
+
 	b, _ := json.Marshal(query)
 	buf := bytes.NewBuffer(b)
 
 	req, _ := makeHTTPReq(buf)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClientFor(*backend).Do(req)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -117,7 +172,9 @@ func doHTTP(ctx *gin.Context, query *pb.Request) {
 
 func doGRPC(ctx *gin.Context, query *pb.Request) {
 	fmt.Println("gRPC pill is taken")
-	resp, err := queryGRPC(query)
+	start := time.Now()
+	resp, err := queryGRPC(ctx.Request.Context(), query)
+	observeRequest("grpc", start, err)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -152,9 +209,19 @@ func ReadResponse(r io.Reader) (*pb.Response, error) {
 	return resp, nil
 }
 
+// backendURL returns the URL the HTTP path should send requests to:
+// "http://unix/" for UNIX-socket backends (the actual socket address is
+// carried by the client's transport, not the URL), or "http://<backend>/"
+// otherwise.
+func backendURL() string {
+	if _, ok := unixSocketAddr(*backend); ok {
+		return "http://unix/"
+	}
+	return fmt.Sprintf("http://%s/", *backend)
+}
+
 func makeHTTPReq(b *bytes.Buffer) (*http.Request, error) {
-	url := fmt.Sprintf("http://%s/", *backend)
-	req, err := http.NewRequest(http.MethodPost, url, b)
+	req, err := http.NewRequest(http.MethodPost, backendURL(), b)
 	if err != nil {
 		return nil, err
 	}
@@ -172,27 +239,53 @@ func getEnv(s, d string) string {
 	return ret
 }
 
-func queryGRPC(req *pb.Request) (*pb.Response, error) {
+// grpcDialOpts builds the DialOptions shared by every connection in the
+// pool: authority override, transport security, round-robin balancing
+// across the addresses each connection resolves to, and client metrics.
+// Rotation across the pool's connections is done by grpcPool.Get.
+func grpcDialOpts() ([]grpc.DialOption, error) {
+	// -tls_server_name, falling back to -host, is used as both the
+	// :authority the backend sees and the SNI presented during the TLS
+	// handshake, so a backend routing on the two together never sees them
+	// disagree.
+	serverName := *tlsServerName
+	if serverName == "" {
+		serverName = *host
+	}
+
 	var opts []grpc.DialOption
-	if *host != "" {
-		opts = append(opts, grpc.WithAuthority(*host))
+	if serverName != "" {
+		opts = append(opts, grpc.WithAuthority(serverName))
 	}
-	if *insecure {
+
+	switch {
+	case *insecure:
 		opts = append(opts, grpc.WithInsecure())
+	default:
+		cfg, err := tlsconfig.Load(tlsconfig.Options{
+			CA:         *tlsCA,
+			Cert:       *tlsCert,
+			Key:        *tlsKey,
+			ServerName: serverName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("loading TLS config: %w", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
 	}
-	fmt.Printf("Dialing to: %s\n", *backend)
-	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-	defer cancel()
-	conn, err := grpc.DialContext(ctx, *backend, opts...)
+
+	opts = append(opts, grpc.WithDefaultServiceConfig(`{"loadBalancingPolicy":"round_robin"}`))
+	opts = append(opts, grpc.WithUnaryInterceptor(grpc_prometheus.UnaryClientInterceptor))
+	return opts, nil
+}
+
+func queryGRPC(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	client, err := pool.Load().Get(ctx)
 	if err != nil {
-		fmt.Printf("failed to dial: %v\n", err)
+		fmt.Printf("failed to get gRPC client: %v\n", err)
 		return nil, err
 	}
-	defer conn.Close()
-
-	client := pb.NewPrimeServiceClient(conn)
-
-	resp, err := client.Get(context.Background(), req)
+	resp, err := client.Call(ctx, req)
 	if err != nil {
 		fmt.Printf("Error calling Get: %+v\n", err)
 		return nil, err
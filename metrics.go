@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var adminPort = flag.String("admin_port", "9090",
+	"Port for the admin server exposing /metrics and /debug/pprof")
+
+var (
+	requestLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "prime_client_request_duration_seconds",
+		Help:    "Latency of backend requests, labeled by backend type and outcome.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"backend", "status"})
+
+	requestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prime_client_request_errors_total",
+		Help: "Count of failed backend requests, labeled by backend type.",
+	}, []string{"backend"})
+)
+
+func init() {
+	prometheus.MustRegister(requestLatency, requestErrors)
+}
+
+// observeRequest records a latency+status observation for a call to the
+// given backend ("http" or "grpc"), started at start, bumping the error
+// counter if err is non-nil.
+func observeRequest(backend string, start time.Time, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+		requestErrors.WithLabelValues(backend).Inc()
+	}
+	requestLatency.WithLabelValues(backend, status).Observe(time.Since(start).Seconds())
+}
+
+// startAdminServer serves /metrics and /debug/pprof/* on -admin_port. It
+// blocks, so callers should run it in a goroutine; a failure here is
+// logged rather than fatal, since it shouldn't take down request serving
+// on the main port.
+func startAdminServer() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	addr := ":" + *adminPort
+	fmt.Printf("Admin server starting: %s\n", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Printf("admin server exited: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveRequestSuccess(t *testing.T) {
+	requestErrors.Reset()
+
+	before := testutil.ToFloat64(requestErrors.WithLabelValues("http"))
+	observeRequest("http", time.Now(), nil)
+	after := testutil.ToFloat64(requestErrors.WithLabelValues("http"))
+
+	if after != before {
+		t.Errorf("requestErrors[http] = %v after a successful call, want unchanged from %v", after, before)
+	}
+	if got := testutil.CollectAndCount(requestLatency, "prime_client_request_duration_seconds"); got == 0 {
+		t.Errorf("requestLatency has no observations after observeRequest")
+	}
+}
+
+func TestObserveRequestError(t *testing.T) {
+	requestErrors.Reset()
+
+	observeRequest("grpc", time.Now(), errors.New("boom"))
+
+	if got := testutil.ToFloat64(requestErrors.WithLabelValues("grpc")); got != 1 {
+		t.Errorf("requestErrors[grpc] = %v after a failed call, want 1", got)
+	}
+}
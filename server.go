@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shutdownTimeout bounds how long Serve waits for in-flight requests to
+// finish once ctx is done.
+const shutdownTimeout = 10 * time.Second
+
+// Server wraps the gin router bound to a single listening socket. Splitting
+// Listen from Serve lets the process hold the socket open across a gRPC
+// pool reload (see redialGRPCPool and its SIGHUP handling in main.go) and
+// lets tests bind an ephemeral port and exercise the server without a
+// real one.
+type Server struct {
+	router *gin.Engine
+}
+
+// NewServer builds the gin router with the routes and static assets this
+// binary serves, reading templates/assets from koPath.
+func NewServer(koPath string) *Server {
+	r := gin.New()
+	r.Use(gin.Logger())
+	r.Use(gin.Recovery())
+
+	r.LoadHTMLFiles(path.Join(koPath, "index.html"))
+	r.Static("/img", path.Join(koPath, "static/img"))
+	r.Static("/css", path.Join(koPath, "static/css"))
+
+	r.GET("/", handlerDef)
+	r.GET("/prime", handler)
+	r.GET("/healthz", handlerLivez)
+	r.GET("/readyz", handlerReadyz)
+
+	return &Server{router: r}
+}
+
+// Listen binds addr (":PORT"-style), returning the raw listener so it can
+// be handed to Serve, and can survive a later configuration reload.
+func (s *Server) Listen(addr string) (net.Listener, error) {
+	return net.Listen("tcp", addr)
+}
+
+// Serve runs the HTTP server on ln until ctx is done, then gracefully
+// shuts it down, waiting up to shutdownTimeout for in-flight requests.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	httpServer := &http.Server{Handler: s.router}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(ln)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		return nil
+	case err := <-errCh:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return err
+	}
+}
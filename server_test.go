@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServerListenServeHealthz(t *testing.T) {
+	koPath := t.TempDir()
+	indexHTML := "<html><body>{{.result}}</body></html>"
+	if err := os.WriteFile(filepath.Join(koPath, "index.html"), []byte(indexHTML), 0o600); err != nil {
+		t.Fatalf("writing stub index.html: %v", err)
+	}
+
+	srv := NewServer(koPath)
+	ln, err := srv.Listen(":0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErrCh := make(chan error, 1)
+	go func() { serveErrCh <- srv.Serve(ctx, ln) }()
+
+	url := "http://" + ln.Addr().String() + "/healthz"
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET /healthz: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	cancel()
+	select {
+	case err := <-serveErrCh:
+		if err != nil {
+			t.Errorf("Serve returned %v after shutdown, want nil", err)
+		}
+	case <-time.After(shutdownTimeout + time.Second):
+		t.Fatal("Serve did not return after ctx was canceled")
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// unixSocketPrefix and unixAbstractSocketPrefix are the schemes
+// backendURL/httpClientFor look for in -backend to route HTTP calls over
+// a UNIX domain socket instead of TCP.
+const (
+	unixSocketPrefix         = "unix://"
+	unixAbstractSocketPrefix = "unix-abstract://"
+)
+
+var (
+	unixHTTPClientOnce sync.Once
+	unixHTTPClient     *http.Client
+)
+
+// unixSocketAddr reports whether backend names a UNIX domain socket, and
+// if so the address to pass to net.Dialer.DialContext for it: the path
+// itself for unix://, or the name prefixed with "@" for unix-abstract://,
+// which Go's net package treats as the Linux abstract namespace.
+func unixSocketAddr(backend string) (addr string, ok bool) {
+	if name, ok := strings.CutPrefix(backend, unixAbstractSocketPrefix); ok {
+		return "@" + name, true
+	}
+	if path, ok := strings.CutPrefix(backend, unixSocketPrefix); ok {
+		return path, true
+	}
+	return "", false
+}
+
+// httpClientFor returns the client to use for backend, dialing a UNIX
+// domain socket when backend has the unix:// or unix-abstract:// scheme
+// and plain TCP otherwise. The UNIX-socket client's transport is built
+// once and cached, since it's pinned to a single socket address for the
+// life of the process.
+func httpClientFor(backend string) *http.Client {
+	addr, ok := unixSocketAddr(backend)
+	if !ok {
+		return http.DefaultClient
+	}
+	unixHTTPClientOnce.Do(func() {
+		unixHTTPClient = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", addr)
+				},
+			},
+		}
+	})
+	return unixHTTPClient
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUnixSocketAddr(t *testing.T) {
+	tests := []struct {
+		name     string
+		backend  string
+		wantAddr string
+		wantOK   bool
+	}{
+		{"unix path", "unix:///var/run/prime.sock", "/var/run/prime.sock", true},
+		{"unix-abstract name", "unix-abstract://prime", "@prime", true},
+		{"plain tcp host", "prime.default.svc.cluster.local", "", false},
+		{"tcp host with port", "prime.default.svc.cluster.local:8080", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, ok := unixSocketAddr(tt.backend)
+			if ok != tt.wantOK || addr != tt.wantAddr {
+				t.Errorf("unixSocketAddr(%q) = (%q, %v), want (%q, %v)", tt.backend, addr, ok, tt.wantAddr, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHTTPClientForRoutesByScheme(t *testing.T) {
+	if c := httpClientFor("prime.default.svc.cluster.local"); c != http.DefaultClient {
+		t.Errorf("httpClientFor(tcp) = %p, want http.DefaultClient", c)
+	}
+
+	unix := httpClientFor("unix:///var/run/prime.sock")
+	if unix == http.DefaultClient {
+		t.Errorf("httpClientFor(unix://...) returned http.DefaultClient, want a dedicated UNIX-socket client")
+	}
+	if again := httpClientFor("unix:///var/run/prime.sock"); again != unix {
+		t.Errorf("httpClientFor(unix://...) returned a different client on a second call, want the cached one")
+	}
+
+	abstract := httpClientFor("unix-abstract://prime")
+	if abstract == http.DefaultClient {
+		t.Errorf("httpClientFor(unix-abstract://...) returned http.DefaultClient, want a dedicated UNIX-socket client")
+	}
+}